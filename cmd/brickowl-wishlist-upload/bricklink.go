@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+//blInventory is the root element of a BrickLink wishlist xml export, also
+//produced by Rebrickable and Studio in the same format.
+type blInventory struct {
+	XMLName xml.Name `xml:"INVENTORY"`
+	Items   []blItem `xml:"ITEM"`
+}
+
+type blItem struct {
+	ItemID string `xml:"ITEMID"`
+	Color  string `xml:"COLOR"`
+	MinQty string `xml:"MINQTY"`
+}
+
+//looksLikeBrickLinkXML detects a BrickLink-format export by extension, or by
+//sniffing for the root <INVENTORY> element when the extension isn't conclusive.
+func looksLikeBrickLinkXML(filePath string, data []byte) bool {
+	if strings.EqualFold(filepath.Ext(filePath), ".xml") {
+		return true
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "<?xml") {
+		return true
+	}
+	return strings.HasPrefix(trimmed, "<INVENTORY")
+}
+
+//parseBrickLinkXML converts a BrickLink-format inventory into a single
+//wishListData, named after the file since BrickLink has no concept of
+//multiple named lists. ITEMID and COLOR are left as BrickLink identifiers -
+//resolving them to Brick Owl BOIDs and color ids happens later, the same as
+//for the json format.
+func parseBrickLinkXML(filePath string, data []byte) (wishListData, error) {
+
+	var inv blInventory
+	if err := xml.Unmarshal(data, &inv); err != nil {
+		return wishListData{}, fmt.Errorf("Error parsing BrickLink xml wishlist: %v", err)
+	}
+
+	list := wishListData{
+		Name: strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)),
+	}
+
+	for _, item := range inv.Items {
+		quantity := item.MinQty
+		if quantity == "" {
+			quantity = "1"
+		}
+
+		list.Pieces = append(list.Pieces, wishListPiece{
+			ID:       item.ItemID,
+			Quantity: quantity,
+			Color:    item.Color,
+		})
+	}
+
+	return list, nil
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jonegerton/brickowl-wishlist-upload/pkg/brickowl"
+)
+
+const (
+	boidsCacheFile    = "brickowl-wishlist-boids.json"
+	colorsCacheFile   = "brickowl-wishlist-colors.json"
+	blColorsCacheFile = "brickowl-wishlist-bl-colors.json"
+)
+
+//cacheTTL is how long a cached BOID or color mapping resolved via a specific
+//id_type is trusted before it's re-resolved from the Brick Owl API. Brick
+//Owl's catalog doesn't change fast enough to need anything shorter.
+//
+//This is a TTL rather than an ETag: Brick Owl's catalog/id_lookup doesn't
+//return one, and there's nothing to send back on a later request to ask "is
+//this still right" - the only conditional-request hook Brick Owl's API
+//offers anywhere is the Retry-After header on rate-limit responses, which is
+//unrelated. A wrong mapping is noticed by TTL expiry (sooner for
+//ambiguousCacheTTL entries, see cacheEntry.ttl) or immediately with
+//-refresh-cache, not by revalidating against the server.
+const cacheTTL = 30 * 24 * time.Hour
+
+//ambiguousCacheTTL applies instead of cacheTTL to entries resolved by the
+//shortest-string heuristic in getBOIDForPart rather than a specific id_type
+//match - those are the ones most likely to have picked the wrong variant, so
+//they're re-resolved sooner.
+const ambiguousCacheTTL = 24 * time.Hour
+
+//cacheEntry is a single cached BOID together with enough metadata to judge
+//whether it's still trustworthy: the id_type query that produced it, and when.
+type cacheEntry struct {
+	Value    string    `json:"value"`
+	Source   string    `json:"source"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+//ttl returns how long this entry is trusted for, based on how confidently it
+//was resolved: "unfiltered" means getBOIDForPart fell back to the
+//shortest-string heuristic over every match rather than a specific id_type.
+func (e cacheEntry) ttl() time.Duration {
+	if e.Source == "unfiltered" {
+		return ambiguousCacheTTL
+	}
+	return cacheTTL
+}
+
+//Cache stores resolved BOID lookups behind a pluggable backend. The default
+//is a json file, which is fine up to a few thousand parts; --cache-backend=bolt
+//switches to a BoltDB-backed store for accounts where re-marshaling the whole
+//map on every run gets slow.
+type Cache interface {
+	Get(key string) (cacheEntry, bool, error)
+	Set(key string, entry cacheEntry) error
+	Close() error
+}
+
+//newCache opens the BOID cache backend named by backend (empty defaults to "file").
+func newCache(backend, path string) (Cache, error) {
+	switch backend {
+	case "", "file":
+		return newFileCache(path)
+	case "bolt":
+		return newBoltCache(path)
+	default:
+		return nil, fmt.Errorf("Unknown -cache-backend '%v' - must be 'file' or 'bolt'", backend)
+	}
+}
+
+//fileCache is the default Cache backend: the whole map is read into memory
+//once and written back out on Close if anything changed.
+type fileCache struct {
+	path    string
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+func newFileCache(path string) (*fileCache, error) {
+	fc := &fileCache{path: path, entries: make(map[string]cacheEntry)}
+
+	err := getLocalData(path, &fc.entries)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return fc, nil
+}
+
+func (fc *fileCache) Get(key string) (cacheEntry, bool, error) {
+	entry, found := fc.entries[key]
+	return entry, found, nil
+}
+
+func (fc *fileCache) Set(key string, entry cacheEntry) error {
+	fc.entries[key] = entry
+	fc.dirty = true
+	return nil
+}
+
+func (fc *fileCache) Close() error {
+	if !fc.dirty {
+		return nil
+	}
+	return setLocalData(fc.path, fc.entries)
+}
+
+//colorCacheFile wraps the color-name-to-id map with a single cached-at
+//timestamp. Unlike BOIDs, colors are fetched from catalog/color_list in one
+//call for the whole catalog, so there's no per-entry source query to record
+//and no need for a pluggable backend - a json file with one timestamp is
+//plenty even for the largest color catalog Brick Owl has.
+type colorCacheFile struct {
+	CachedAt time.Time         `json:"cached_at"`
+	Colors   map[string]string `json:"colors"`
+}
+
+//colorCatalog caches the Brick Owl color catalog for the duration of a run, so
+//getColors and getBLColors don't both fetch it on a cold disk cache.
+var colorCatalog map[string]brickowl.Color
+
+func getColorCatalog(ctx context.Context, client *brickowl.Client) (map[string]brickowl.Color, error) {
+	if colorCatalog != nil {
+		return colorCatalog, nil
+	}
+
+	colorData, err := client.Colors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	colorCatalog = colorData
+	return colorData, nil
+}
+
+func getColors(ctx context.Context, client *brickowl.Client) (map[string]string, error) {
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Error in getColors(): %v", err)
+		}
+	}()
+
+	if colorMap, ok := readColorCache(colorsCacheFile); ok {
+		return colorMap, nil
+	}
+
+	colorData, err := getColorCatalog(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	colorMap := make(map[string]string)
+	for ID, color := range colorData {
+		colorMap[strings.ToLower(color.Name)] = ID
+	}
+
+	if err := writeColorCache(colorsCacheFile, colorMap); err != nil {
+		return nil, err
+	}
+
+	return colorMap, nil
+}
+
+//getBLColors maps BrickLink color ids to Brick Owl color ids, for wishlists
+//imported from BrickLink-format xml. It's cached on disk the same way as getColors.
+func getBLColors(ctx context.Context, client *brickowl.Client) (map[string]string, error) {
+
+	var err error
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Error in getBLColors(): %v", err)
+		}
+	}()
+
+	if colorMap, ok := readColorCache(blColorsCacheFile); ok {
+		return colorMap, nil
+	}
+
+	colorData, err := getColorCatalog(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	colorMap := make(map[string]string)
+	for ID, color := range colorData {
+		if color.BLID == "" {
+			continue
+		}
+		colorMap[color.BLID] = ID
+	}
+
+	if err := writeColorCache(blColorsCacheFile, colorMap); err != nil {
+		return nil, err
+	}
+
+	return colorMap, nil
+}
+
+//readColorCache returns the cached color map at path, unless -refresh-cache
+//was passed, the file doesn't exist, or the cache is older than cacheTTL.
+func readColorCache(path string) (map[string]string, bool) {
+	if refreshCache {
+		return nil, false
+	}
+
+	var cached colorCacheFile
+	if err := getLocalData(path, &cached); err != nil {
+		return nil, false
+	}
+
+	if time.Since(cached.CachedAt) >= cacheTTL {
+		return nil, false
+	}
+
+	return cached.Colors, true
+}
+
+func writeColorCache(path string, colors map[string]string) error {
+	return setLocalData(path, colorCacheFile{CachedAt: time.Now(), Colors: colors})
+}
+
+//getBOIDForPart looks up the Brick Owl part id (BOID) for a local part id,
+//matching first on the BrickLink item number when we know the data came from
+//a BrickLink-format export, then ldraw id (which fits bricklink closely
+//otherwise), then design_id, then all matches. Results are cached in boidCache
+//keyed by partID, recording which id_type resolved them and when, so a wrong
+//guess from the shortest-string heuristic below can be noticed and re-resolved
+//once its entry's ttl() has passed, or immediately with -refresh-cache.
+func getBOIDForPart(ctx context.Context, client *brickowl.Client, boidCache Cache, partID string) (string, error) {
+
+	if !refreshCache {
+		entry, found, err := boidCache.Get(partID)
+		if err != nil {
+			return "", err
+		}
+		if found && time.Since(entry.CachedAt) < entry.ttl() {
+			return entry.Value, nil
+		}
+	}
+
+	idTypes := []string{}
+	if blSource {
+		idTypes = append(idTypes, "bl_item_no")
+	}
+	idTypes = append(idTypes, "ldraw", "design_id", "")
+
+	var boids []string
+	var err error
+	var resolvedVia string
+	for _, idType := range idTypes {
+		boids, err = client.LookupBOID(ctx, partID, idType)
+		if err != nil {
+			return "", err
+		}
+		if len(boids) > 0 {
+			resolvedVia = idType
+			break
+		}
+	}
+
+	if len(boids) == 0 {
+		return "", fmt.Errorf("Failed to lookup any boids for partID '%v'", partID)
+	}
+
+	//Need to do some parsing on the response, eg above query returns
+	//{"boids":["901078-98","901078-100","901078-95","901078-97","901078-101","901078"]}
+	//Initial experimnetation is to take shortest value
+	boid := boids[0]
+	for _, id := range boids[1:] {
+		if len(id) < len(boid) {
+			boid = id
+		}
+	}
+
+	if resolvedVia == "" {
+		resolvedVia = "unfiltered"
+	}
+
+	if err := boidCache.Set(partID, cacheEntry{Value: boid, Source: resolvedVia, CachedAt: time.Now()}); err != nil {
+		return "", err
+	}
+
+	return boid, nil
+}
+
+func getLocalData(filePath string, data interface{}) error {
+
+	if _, err := os.Stat(filePath); err != nil {
+		return err
+	}
+
+	//check for saved data
+	bytes, readErr := ioutil.ReadFile(filePath)
+	if readErr != nil {
+		return fmt.Errorf("Could not read data from file '%v'", filePath)
+	}
+
+	if err := json.Unmarshal(bytes, data); err != nil {
+		return fmt.Errorf("Error parsing data: %v", err)
+	}
+
+	return nil
+}
+
+func setLocalData(filePath string, data interface{}) error {
+
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filePath, bytes, 0644)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+//go:build boltcache
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltCacheBucket = []byte("boids")
+
+//boltCache is the -cache-backend=bolt implementation, built only when the
+//boltcache tag is passed (go build -tags boltcache). It avoids re-marshaling
+//a giant json map on every run, for accounts with tens of thousands of parts.
+type boltCache struct {
+	db *bolt.DB
+}
+
+func newBoltCache(path string) (Cache, error) {
+	db, err := bolt.Open(path, 0644, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open bolt cache '%v': %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltCacheBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltCache{db: db}, nil
+}
+
+func (c *boltCache) Get(key string) (cacheEntry, bool, error) {
+	var entry cacheEntry
+	found := false
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltCacheBucket).Get([]byte(key))
+		if value == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(value, &entry)
+	})
+
+	return entry, found, err
+}
+
+func (c *boltCache) Set(key string, entry cacheEntry) error {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltCacheBucket).Put([]byte(key), bytes)
+	})
+}
+
+func (c *boltCache) Close() error {
+	return c.db.Close()
+}
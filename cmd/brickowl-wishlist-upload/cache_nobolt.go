@@ -0,0 +1,12 @@
+//go:build !boltcache
+
+package main
+
+import "fmt"
+
+//newBoltCache is stubbed out in default builds so the bbolt dependency is
+//only pulled in when it's actually wanted - build with -tags boltcache to get
+//the real implementation in cache_bolt.go.
+func newBoltCache(path string) (Cache, error) {
+	return nil, fmt.Errorf("this build was not compiled with bolt cache support - rebuild with -tags boltcache, or use -cache-backend=file")
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestCacheEntryTTL(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"ldraw match gets the full ttl", "ldraw", "cacheTTL"},
+		{"design_id match gets the full ttl", "design_id", "cacheTTL"},
+		{"bl_item_no match gets the full ttl", "bl_item_no", "cacheTTL"},
+		{"unfiltered heuristic match gets the shorter ttl", "unfiltered", "ambiguousCacheTTL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := cacheEntry{Source: tt.source}
+			want := cacheTTL
+			if tt.want == "ambiguousCacheTTL" {
+				want = ambiguousCacheTTL
+			}
+			if got := entry.ttl(); got != want {
+				t.Errorf("ttl() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/jonegerton/brickowl-wishlist-upload/pkg/brickowl"
+)
+
+var (
+	apiKey     string
+	dataFile   string
+	purgeLists bool
+	verbose    bool
+	rps        float64
+	dryRun     bool
+	onConflict string
+	silent     bool
+	reportFile string
+
+	refreshCache bool
+	cacheBackend string
+
+	//blSource records whether dataFile was detected as a BrickLink-format XML
+	//export, so BOID and color lookups know to use BrickLink identifiers.
+	blSource bool
+
+	progress *progressBar
+	report   *runReport
+)
+
+const dummyListName = "empty placeholder list"
+
+func init() {
+
+	flag.StringVar(&apiKey, "apikey", "", "api key registered on Brick Owl.")
+	flag.StringVar(&dataFile, "datafile", "", "wishlist data file - either this tool's own json format, or a BrickLink-format xml export.")
+	flag.BoolVar(&verbose, "verbose", false, "Enable verbose logging output.")
+	flag.BoolVar(&purgeLists, "purgelists", false, "Purge existing lists that aren't present in the data file.")
+	flag.Float64Var(&rps, "rps", 2, "Maximum Brick Owl API requests per second.")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the planned diff without making any changes.")
+	flag.StringVar(&onConflict, "on-conflict", "overwrite", "Policy for lots that exist with a different quantity than the data file: overwrite, skip, or merge-max-qty.")
+	flag.BoolVar(&silent, "silent", false, "Suppress the progress bar.")
+	flag.StringVar(&reportFile, "report-file", "", "Optional path to write a JSON run report to.")
+	flag.BoolVar(&refreshCache, "refresh-cache", false, "Ignore cached BOID and color lookups and re-resolve everything from the API.")
+	flag.StringVar(&cacheBackend, "cache-backend", "file", "BOID cache backend: file (default) or bolt.")
+}
+
+func main() {
+	flag.Parse()
+
+	//Check mandatory flags
+	if apiKey == "" || dataFile == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+//run does the actual work, returning an error rather than calling log.Fatal
+//itself - log.Fatal calls os.Exit, which skips every deferred cleanup (the
+//BOID/color cache flush, the ctx cancel) between wherever it's called and the
+//top of the stack. Returning instead means main() is the only place that can
+//end the process, so a run that dies partway through still persists whatever
+//it resolved on the way.
+func run() error {
+
+	startedAt := time.Now()
+
+	switch onConflict {
+	case "overwrite", "skip", "merge-max-qty":
+	default:
+		return fmt.Errorf("Invalid -on-conflict value '%v' - must be one of overwrite, skip, merge-max-qty", onConflict)
+	}
+
+	//Cancel any in-flight run (and its retries/backoff) on Ctrl-C, rather than
+	//leaving a multi-hour upload to either finish or be killed mid-request.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		<-sigCh
+		log.Println("Received interrupt, cancelling run...")
+		cancel()
+	}()
+
+	client := brickowl.NewClient(apiKey, rps)
+	client.Verbose = verbose
+
+	report = newRunReport()
+
+	wishListData, err := getWishListData()
+	if err != nil {
+		return err
+	}
+
+	colors, err := getColors(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	//BrickLink exports give a BrickLink color id rather than a color name, so
+	//they need their own name-less BOID/color-id mapping
+	var blColors map[string]string
+	if blSource {
+		blColors, err = getBLColors(ctx, client)
+		if err != nil {
+			return err
+		}
+	}
+
+	boidCache, err := newCache(cacheBackend, boidsCacheFile)
+	if err != nil {
+		return err
+	}
+	//Flush whatever got resolved this run even if the rest of run() returns an
+	//error partway through, so a run that dies on one bad lot doesn't re-spend
+	//API calls re-resolving BOIDs it already had on a later run.
+	defer func() {
+		if closeErr := boidCache.Close(); closeErr != nil {
+			log.Printf("Error closing BOID cache: %v", closeErr)
+		}
+	}()
+
+	totalPieces := 0
+	for _, list := range wishListData {
+		totalPieces += len(list.Pieces)
+	}
+	progress = newProgressBar(totalPieces)
+
+	boWishLists, err := client.ListWishlists(ctx)
+	if err != nil {
+		return err
+	}
+
+	//We create a dummy list, as brick owl doesn't allow us to delete all lists for a user
+	//Creating a dummy means we can delete all other lists if necessary
+	dummyFound := false
+	for _, boList := range boWishLists {
+		if boList.Name == dummyListName {
+			dummyFound = true
+		}
+	}
+	if !dummyFound {
+		if dryRun {
+			logReport("[dry-run] would create dummy placeholder list '%v'", dummyListName)
+		} else if _, err := client.CreateWishlist(ctx, dummyListName, ""); err != nil {
+			return err
+		}
+	}
+
+	//Purge any lists from BO that aren't present in our data, rather than destroying
+	//and recreating lists we do recognise - see reconcileList for those.
+	if purgeLists {
+		for _, boList := range boWishLists {
+			if boList.Name == dummyListName {
+				continue
+			}
+
+			found := false
+			for _, wishList := range wishListData {
+				if wishList.Name == boList.Name {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+
+			if dryRun {
+				logReport("[dry-run] would delete list '%v' (not present in data file)", boList.Name)
+				continue
+			}
+
+			if err := client.DeleteWishlist(ctx, boList.ID); err != nil {
+				return err
+			}
+			report.ListsDeleted = append(report.ListsDeleted, boList.Name)
+		}
+	}
+
+	//Now reconcile each list against what's already on Brick Owl. reconcileList
+	//only returns an error when ctx has been cancelled - anything short of that
+	//is logged and recorded on the report instead, so one bad list doesn't stop
+	//the rest from being reconciled.
+	for _, list := range wishListData {
+		progress.startList(list.Name, len(list.Pieces))
+		if err := reconcileList(ctx, client, list, boWishLists, boidCache, colors, blColors); err != nil {
+			return err
+		}
+	}
+
+	progress.finish()
+
+	report.APICalls = client.Calls()
+	report.Duration = time.Since(startedAt).Round(time.Second).String()
+	report.print()
+
+	if reportFile != "" {
+		if err := writeReport(reportFile, report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func logReport(format string, a ...interface{}) {
+	log.Printf(format, a...)
+}
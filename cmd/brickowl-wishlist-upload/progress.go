@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+//progressBar renders a single overwriting line to stderr showing pieces
+//processed (per-list and overall), current rps, eta, and errors so far. It's
+//disabled by --silent or when stderr isn't a terminal, so piped/CI output stays clean.
+type progressBar struct {
+	enabled bool
+
+	total int
+	done  int
+	errs  int
+
+	listName  string
+	listTotal int
+	listDone  int
+
+	startedAt time.Time
+	lastDraw  time.Time
+}
+
+func newProgressBar(total int) *progressBar {
+	return &progressBar{
+		enabled:   !silent && isTerminal(os.Stderr),
+		total:     total,
+		startedAt: time.Now(),
+	}
+}
+
+//startList resets the per-list counters when reconciliation moves on to a new list.
+func (p *progressBar) startList(name string, pieces int) {
+	p.listName = name
+	p.listTotal = pieces
+	p.listDone = 0
+	p.draw(false)
+}
+
+//increment marks one more piece processed, optionally as an error, and redraws.
+func (p *progressBar) increment(isErr bool) {
+	p.done++
+	p.listDone++
+	if isErr {
+		p.errs++
+	}
+	p.draw(false)
+}
+
+func (p *progressBar) finish() {
+	p.draw(true)
+}
+
+func (p *progressBar) draw(final bool) {
+	if !p.enabled {
+		return
+	}
+
+	//Redraw at most a few times a second so large runs don't spam the terminal
+	if !final && time.Since(p.lastDraw) < 100*time.Millisecond {
+		return
+	}
+	p.lastDraw = time.Now()
+
+	elapsed := time.Since(p.startedAt).Seconds()
+	rps := 0.0
+	if elapsed > 0 {
+		rps = float64(p.done) / elapsed
+	}
+
+	eta := "?"
+	if rps > 0 && p.total > p.done {
+		eta = time.Duration(float64(p.total-p.done) / rps * float64(time.Second)).Round(time.Second).String()
+	}
+
+	fmt.Fprintf(os.Stderr, "\r%-30.30v %v/%v | overall %v/%v, %.1f rps, eta %v, %v errors    ",
+		p.listName, p.listDone, p.listTotal, p.done, p.total, rps, eta, p.errs)
+
+	if final {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+//isTerminal reports whether f looks like an interactive terminal rather than
+//a pipe or redirected file, without pulling in a terminal-handling dependency.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jonegerton/brickowl-wishlist-upload/pkg/brickowl"
+)
+
+//lotTarget is the desired state for a single BOID/color combination, derived
+//from the local wishListData once BOIDs and colors have been resolved.
+type lotTarget struct {
+	boid     string
+	colorID  string
+	quantity string
+}
+
+//reconcileList brings a single Brick Owl wishlist in line with a local
+//wishListData entry: it creates the list if Brick Owl doesn't have it yet,
+//then diffs the existing lots against the target lots and issues only the
+//create_lot, update, and delete_lot calls needed to reconcile them - rather
+//than destroying and rebuilding the whole list on every run.
+//
+//A failing create/update/delete_lot call is logged and recorded on the report
+//rather than returned, the same way an unresolved BOID/color is handled in
+//resolveTargets, so one bad lot (or one list Brick Owl won't cooperate on)
+//doesn't take down the rest of a multi-hour, multi-list run. The only error
+//reconcileList returns is a cancelled ctx, which is the one case actually
+//worth stopping the whole run for.
+func reconcileList(ctx context.Context, client *brickowl.Client, list wishListData, boWishLists []brickowl.WishList, boidCache Cache, colors map[string]string, blColors map[string]string) error {
+
+	listID := ""
+	for _, boList := range boWishLists {
+		if boList.Name == list.Name {
+			listID = boList.ID
+			break
+		}
+	}
+
+	if listID == "" {
+		if dryRun {
+			logReport("[dry-run] would create list '%v'", list.Name)
+		} else {
+			created, err := client.CreateWishlist(ctx, list.Name, list.Description)
+			if err != nil {
+				if fatalErr(ctx, err) {
+					return err
+				}
+				logReport("Error creating wish list '%v': %v - skipping list", list.Name, err)
+				report.listFailed(list.Name)
+				return nil
+			}
+			listID = created.ID
+			report.ListsCreated = append(report.ListsCreated, list.Name)
+		}
+	}
+
+	targets, err := resolveTargets(ctx, client, list, boidCache, colors, blColors)
+	if err != nil {
+		return err
+	}
+
+	//Nothing to diff against yet in dry-run mode, as the list doesn't exist on Brick Owl
+	if listID == "" {
+		for _, t := range targets {
+			logReport("[dry-run] would create lot boid=%v color=%v qty=%v on list '%v'", t.boid, t.colorID, t.quantity, list.Name)
+		}
+		return nil
+	}
+
+	existingLots, err := client.ListLots(ctx, listID)
+	if err != nil {
+		if fatalErr(ctx, err) {
+			return err
+		}
+		logReport("Error listing lots for wish list '%v': %v - skipping list", list.Name, err)
+		report.listFailed(list.Name)
+		return nil
+	}
+
+	existingByKey := make(map[string]brickowl.Lot, len(existingLots))
+	for _, lot := range existingLots {
+		existingByKey[lotKey(lot.BOID, lot.ColorID)] = lot
+	}
+
+	for key, t := range targets {
+		existing, found := existingByKey[key]
+		delete(existingByKey, key)
+
+		if !found {
+			if dryRun {
+				logReport("[dry-run] would create lot boid=%v color=%v qty=%v on list '%v'", t.boid, t.colorID, t.quantity, list.Name)
+				continue
+			}
+			if err := createLot(ctx, client, listID, t); err != nil {
+				if fatalErr(ctx, err) {
+					return err
+				}
+				logReport("Error creating lot boid=%v color=%v on list '%v': %v - skipping", t.boid, t.colorID, list.Name, err)
+				report.lotFailed()
+				continue
+			}
+			report.LotsAdded++
+			continue
+		}
+
+		if existing.MinimumQuantity == t.quantity {
+			continue
+		}
+
+		quantity, changed := resolveConflict(existing.MinimumQuantity, t.quantity)
+		if !changed {
+			logReport("[skip] lot boid=%v color=%v on list '%v' kept at qty=%v (on-conflict=%v)", t.boid, t.colorID, list.Name, existing.MinimumQuantity, onConflict)
+			report.LotsSkipped++
+			continue
+		}
+
+		if dryRun {
+			logReport("[dry-run] would update lot boid=%v color=%v on list '%v' from qty=%v to qty=%v", t.boid, t.colorID, list.Name, existing.MinimumQuantity, quantity)
+			continue
+		}
+
+		if err := client.UpdateLot(ctx, listID, existing.ID, quantity); err != nil {
+			if fatalErr(ctx, err) {
+				return err
+			}
+			logReport("Error updating lot boid=%v color=%v on list '%v': %v - skipping", t.boid, t.colorID, list.Name, err)
+			report.lotFailed()
+			continue
+		}
+		report.LotsUpdated++
+	}
+
+	//Anything left over is on Brick Owl but no longer wanted locally
+	for _, lot := range existingByKey {
+		if dryRun {
+			logReport("[dry-run] would delete lot boid=%v color=%v on list '%v'", lot.BOID, lot.ColorID, list.Name)
+			continue
+		}
+		if err := client.DeleteLot(ctx, listID, lot.ID); err != nil {
+			if fatalErr(ctx, err) {
+				return err
+			}
+			logReport("Error deleting lot boid=%v color=%v on list '%v': %v - skipping", lot.BOID, lot.ColorID, list.Name, err)
+			report.lotFailed()
+			continue
+		}
+		report.LotsDeleted++
+	}
+
+	return nil
+}
+
+//fatalErr reports whether err represents ctx being cancelled (eg Ctrl-C)
+//rather than a single failed API call, which is the only case reconcileList
+//treats as run-ending instead of logging and moving on to the next item.
+func fatalErr(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil
+}
+
+//resolveTargets resolves each piece's BOID and color, caching newly discovered
+//BOIDs in boidCache, and returns the deduplicated set of lots the list should end up with.
+//Pieces from a BrickLink-format import carry a BrickLink color id rather than
+//a color name, so they're looked up via blColors instead of colors.
+func resolveTargets(ctx context.Context, client *brickowl.Client, list wishListData, boidCache Cache, colors map[string]string, blColors map[string]string) (map[string]lotTarget, error) {
+
+	targets := make(map[string]lotTarget, len(list.Pieces))
+
+	for _, piece := range list.Pieces {
+
+		boid := piece.BOID
+		if boid == "" {
+			var err error
+			boid, err = getBOIDForPart(ctx, client, boidCache, piece.ID)
+			if err != nil {
+				if fatalErr(ctx, err) {
+					return nil, err
+				}
+				logReport("Error getting BOID for piece with ID '%v' on wish list '%v' - Skipping", piece.ID, list.Name)
+				report.unresolvedPart(piece.ID)
+				progress.increment(true)
+				continue
+			}
+		}
+
+		var colorID string
+		var ok bool
+		if blSource {
+			colorID, ok = blColors[piece.Color]
+		} else {
+			colorID, ok = colors[strings.ToLower(piece.Color)]
+		}
+		if !ok {
+			logReport("Error getting color for piece with ID '%v' on wish list '%v' - Skipping", piece.ID, list.Name)
+			report.unresolvedColor(piece.Color)
+			progress.increment(true)
+			continue
+		}
+
+		quantity := piece.Quantity
+		if quantity == "" {
+			quantity = "1"
+		}
+
+		targets[lotKey(boid, colorID)] = lotTarget{boid: boid, colorID: colorID, quantity: quantity}
+		progress.increment(false)
+	}
+
+	return targets, nil
+}
+
+func lotKey(boid, colorID string) string {
+	return boid + "|" + colorID
+}
+
+//resolveConflict applies the --on-conflict policy when a lot already exists on
+//Brick Owl with a different quantity than the data file wants, returning the
+//quantity to apply and whether that's actually a change from what's there.
+func resolveConflict(existingQty, wantQty string) (quantity string, changed bool) {
+
+	switch onConflict {
+	case "skip":
+		return existingQty, false
+
+	case "merge-max-qty":
+		existing, errA := strconv.Atoi(existingQty)
+		want, errB := strconv.Atoi(wantQty)
+		if errA != nil || errB != nil || want > existing {
+			return wantQty, true
+		}
+		return existingQty, false
+
+	default: // "overwrite"
+		return wantQty, true
+	}
+}
+
+//createLot creates a lot then, if the wanted quantity isn't 1, follows up
+//with an update - Brick Owl doesn't accept a quantity on creation.
+func createLot(ctx context.Context, client *brickowl.Client, listID string, t lotTarget) error {
+
+	lot, err := client.CreateLot(ctx, listID, t.boid, t.colorID)
+	if err != nil {
+		return err
+	}
+
+	if t.quantity != "1" {
+		return client.UpdateLot(ctx, listID, lot.ID, t.quantity)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jonegerton/brickowl-wishlist-upload/pkg/brickowl"
+)
+
+func TestResolveConflict(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		onConflict  string
+		existingQty string
+		wantQty     string
+		quantity    string
+		changed     bool
+	}{
+		{"overwrite always takes the data file value", "overwrite", "5", "2", "2", true},
+		{"skip always keeps what's there", "skip", "5", "2", "5", false},
+		{"merge-max-qty keeps the existing value when it's bigger", "merge-max-qty", "5", "2", "5", false},
+		{"merge-max-qty takes the data file value when it's bigger", "merge-max-qty", "2", "5", "5", true},
+		{"merge-max-qty falls back to overwrite on unparseable quantities", "merge-max-qty", "lots", "5", "5", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onConflict = tt.onConflict
+
+			quantity, changed := resolveConflict(tt.existingQty, tt.wantQty)
+			if quantity != tt.quantity || changed != tt.changed {
+				t.Errorf("resolveConflict(%q, %q) = (%q, %v), want (%q, %v)",
+					tt.existingQty, tt.wantQty, quantity, changed, tt.quantity, tt.changed)
+			}
+		})
+	}
+}
+
+//stubbedClient returns a *brickowl.Client wired up to an httptest.Server that
+//serves fixed responses for wishlist/lots and records every create_lot,
+//update, and delete_lot call it receives.
+func stubbedClient(t *testing.T, existingLotsJSON string) (*brickowl.Client, *[]string) {
+	t.Helper()
+
+	var calls []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/wishlist/lots"):
+			fmt.Fprint(w, existingLotsJSON)
+		case strings.HasPrefix(r.URL.Path, "/wishlist/create_lot"):
+			calls = append(calls, fmt.Sprintf("create boid=%v color=%v", r.FormValue("boid"), r.FormValue("color_id")))
+			fmt.Fprintf(w, `{"lot_id":"new-%v-%v"}`, r.FormValue("boid"), r.FormValue("color_id"))
+		case strings.HasPrefix(r.URL.Path, "/wishlist/update"):
+			calls = append(calls, fmt.Sprintf("update lot=%v qty=%v", r.FormValue("lot_id"), r.FormValue("minimum_quantity")))
+			fmt.Fprint(w, `{}`)
+		case strings.HasPrefix(r.URL.Path, "/wishlist/delete_lot"):
+			calls = append(calls, fmt.Sprintf("delete lot=%v", r.FormValue("lot_id")))
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request to %v", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := brickowl.NewClient("test-key", 1000)
+	client.BaseURL = server.URL + "/"
+	return client, &calls
+}
+
+func TestReconcileListDiffsAgainstExistingLots(t *testing.T) {
+
+	dryRun = false
+	onConflict = "overwrite"
+	blSource = false
+	report = newRunReport()
+	progress = newProgressBar(0)
+
+	client, calls := stubbedClient(t, `[
+		{"lot_id":"1","boid":"3001","color_id":"5","minimum_quantity":"2"},
+		{"lot_id":"2","boid":"3002","color_id":"5","minimum_quantity":"3"}
+	]`)
+
+	list := wishListData{
+		Name: "test list",
+		Pieces: []wishListPiece{
+			{BOID: "3001", Color: "red", Quantity: "4"},  //existing, different qty -> update
+			{BOID: "3002", Color: "red", Quantity: "3"},  //existing, same qty -> no-op
+			{BOID: "3003", Color: "blue", Quantity: "1"}, //not present on BO -> create
+			//3002/red is kept, so only lots for boids not in the data file at all get deleted;
+			//add a second existing-only lot to exercise that path.
+		},
+	}
+	boWishLists := []brickowl.WishList{{ID: "42", Name: "test list"}}
+	colors := map[string]string{"red": "5", "blue": "9"}
+
+	if err := reconcileList(context.Background(), client, list, boWishLists, newTestCache(), colors, nil); err != nil {
+		t.Fatalf("reconcileList() error = %v", err)
+	}
+
+	want := []string{
+		"update lot=1 qty=4",
+		"create boid=3003 color=9",
+	}
+	if !sameElements(*calls, want) {
+		t.Errorf("calls = %v, want %v", *calls, want)
+	}
+
+	if report.LotsAdded != 1 {
+		t.Errorf("LotsAdded = %v, want 1", report.LotsAdded)
+	}
+	if report.LotsUpdated != 1 {
+		t.Errorf("LotsUpdated = %v, want 1", report.LotsUpdated)
+	}
+}
+
+func TestReconcileListDeletesLotsNotInDataFile(t *testing.T) {
+
+	dryRun = false
+	onConflict = "overwrite"
+	blSource = false
+	report = newRunReport()
+	progress = newProgressBar(0)
+
+	client, calls := stubbedClient(t, `[{"lot_id":"1","boid":"3001","color_id":"5","minimum_quantity":"2"}]`)
+
+	list := wishListData{Name: "test list"} //no pieces - everything existing is now unwanted
+	boWishLists := []brickowl.WishList{{ID: "42", Name: "test list"}}
+
+	if err := reconcileList(context.Background(), client, list, boWishLists, newTestCache(), map[string]string{}, nil); err != nil {
+		t.Fatalf("reconcileList() error = %v", err)
+	}
+
+	want := []string{"delete lot=1"}
+	if !sameElements(*calls, want) {
+		t.Errorf("calls = %v, want %v", *calls, want)
+	}
+	if report.LotsDeleted != 1 {
+		t.Errorf("LotsDeleted = %v, want 1", report.LotsDeleted)
+	}
+}
+
+func TestReconcileListSkipPolicyLeavesExistingQuantity(t *testing.T) {
+
+	dryRun = false
+	onConflict = "skip"
+	blSource = false
+	report = newRunReport()
+	progress = newProgressBar(0)
+
+	client, calls := stubbedClient(t, `[{"lot_id":"1","boid":"3001","color_id":"5","minimum_quantity":"2"}]`)
+
+	list := wishListData{
+		Name:   "test list",
+		Pieces: []wishListPiece{{BOID: "3001", Color: "red", Quantity: "9"}},
+	}
+	boWishLists := []brickowl.WishList{{ID: "42", Name: "test list"}}
+	colors := map[string]string{"red": "5"}
+
+	if err := reconcileList(context.Background(), client, list, boWishLists, newTestCache(), colors, nil); err != nil {
+		t.Fatalf("reconcileList() error = %v", err)
+	}
+
+	if len(*calls) != 0 {
+		t.Errorf("calls = %v, want none (on-conflict=skip shouldn't touch the lot)", *calls)
+	}
+	if report.LotsSkipped != 1 {
+		t.Errorf("LotsSkipped = %v, want 1", report.LotsSkipped)
+	}
+}
+
+//testCache is a minimal in-memory Cache for tests that don't exercise BOID
+//resolution (every piece in these tests already carries its own BOID).
+type testCache struct {
+	entries map[string]cacheEntry
+}
+
+func newTestCache() *testCache {
+	return &testCache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *testCache) Get(key string) (cacheEntry, bool, error) {
+	entry, found := c.entries[key]
+	return entry, found, nil
+}
+
+func (c *testCache) Set(key string, entry cacheEntry) error {
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *testCache) Close() error {
+	return nil
+}
+
+func sameElements(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, g := range got {
+		seen[g]++
+	}
+	for _, w := range want {
+		if seen[w] == 0 {
+			return false
+		}
+		seen[w]--
+	}
+	return true
+}
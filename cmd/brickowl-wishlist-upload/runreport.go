@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+)
+
+//runReport accumulates a structured summary of what a run did, for printing
+//at the end and optionally writing out as json via --report-file.
+type runReport struct {
+	ListsCreated []string `json:"lists_created"`
+	ListsDeleted []string `json:"lists_deleted"`
+
+	LotsAdded   int `json:"lots_added"`
+	LotsUpdated int `json:"lots_updated"`
+	LotsSkipped int `json:"lots_skipped"`
+	LotsDeleted int `json:"lots_deleted"`
+	LotsFailed  int `json:"lots_failed"`
+
+	UnresolvedParts  []string `json:"unresolved_parts"`
+	UnresolvedColors []string `json:"unresolved_colors"`
+	ListsFailed      []string `json:"lists_failed"`
+
+	APICalls int    `json:"api_calls"`
+	Duration string `json:"duration"`
+
+	seenUnresolvedParts  map[string]bool
+	seenUnresolvedColors map[string]bool
+}
+
+func newRunReport() *runReport {
+	return &runReport{
+		seenUnresolvedParts:  make(map[string]bool),
+		seenUnresolvedColors: make(map[string]bool),
+	}
+}
+
+func (r *runReport) unresolvedPart(partID string) {
+	if r.seenUnresolvedParts[partID] {
+		return
+	}
+	r.seenUnresolvedParts[partID] = true
+	r.UnresolvedParts = append(r.UnresolvedParts, partID)
+}
+
+func (r *runReport) unresolvedColor(color string) {
+	if r.seenUnresolvedColors[color] {
+		return
+	}
+	r.seenUnresolvedColors[color] = true
+	r.UnresolvedColors = append(r.UnresolvedColors, color)
+}
+
+//lotFailed records that a single create/update/delete_lot call failed and was
+//skipped rather than stopping the run.
+func (r *runReport) lotFailed() {
+	r.LotsFailed++
+}
+
+//listFailed records that a whole list couldn't be reconciled (eg it couldn't
+//be created or its lots couldn't be listed), so the run moved on to the next one.
+func (r *runReport) listFailed(name string) {
+	r.ListsFailed = append(r.ListsFailed, name)
+}
+
+//print writes a human-readable summary of the report via logReport.
+func (r *runReport) print() {
+	logReport("Run summary: %v lists created, %v lists deleted, %v lots added, %v lots updated, %v lots skipped, %v lots deleted, %v lots failed, %v unresolved parts, %v unresolved colors, %v lists failed, %v api calls, took %v",
+		len(r.ListsCreated), len(r.ListsDeleted), r.LotsAdded, r.LotsUpdated, r.LotsSkipped, r.LotsDeleted, r.LotsFailed, len(r.UnresolvedParts), len(r.UnresolvedColors), len(r.ListsFailed), r.APICalls, r.Duration)
+
+	if len(r.UnresolvedParts) > 0 {
+		logReport("Unresolved part ids: %v", r.UnresolvedParts)
+	}
+	if len(r.UnresolvedColors) > 0 {
+		logReport("Unresolved colors: %v", r.UnresolvedColors)
+	}
+	if len(r.ListsFailed) > 0 {
+		logReport("Lists failed: %v", r.ListsFailed)
+	}
+}
+
+func writeReport(path string, r *runReport) error {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bytes, 0644)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+type wishListPiece struct {
+	ID       string `json:"id"`
+	Quantity string `json:"qty"`
+	Color    string `json:"color"`
+	BOID     string `json:"boid"`
+}
+
+type wishListData struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Pieces      []wishListPiece `json:"pieces"`
+}
+
+//getWishListData reads dataFile and auto-detects its format: this tool's own
+//json, or a BrickLink-format xml export (also produced by Rebrickable and
+//Studio). BrickLink exports have no concept of multiple named lists, so they
+//come back as a single wishListData named after the file.
+func getWishListData() (lists []wishListData, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Error in getWishListData(): %v", err)
+		}
+	}()
+
+	data, readErr := ioutil.ReadFile(dataFile)
+	if readErr != nil {
+		err = fmt.Errorf("Could not read wishlist data from file '%v': %v", dataFile, readErr)
+		return
+	}
+
+	if looksLikeBrickLinkXML(dataFile, data) {
+		blSource = true
+
+		var list wishListData
+		if list, err = parseBrickLinkXML(dataFile, data); err != nil {
+			return
+		}
+		lists = []wishListData{list}
+		return
+	}
+
+	if err = json.Unmarshal(data, &lists); err != nil {
+		err = fmt.Errorf("Error parsing wishlist data: %v", err)
+		return
+	}
+	return
+
+}
@@ -0,0 +1,141 @@
+//Package brickowl is a typed client for the Brick Owl API
+//(https://www.brickowl.com/api), used by the brickowl-wishlist-upload CLI and
+//reusable from other tools.
+package brickowl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.brickowl.com/v1/"
+
+//Client talks to the Brick Owl API, enforcing a configurable requests-per-second
+//cap and retrying transient failures with exponential backoff and jitter.
+type Client struct {
+	//APIKey is the api key registered on Brick Owl.
+	APIKey string
+	//BaseURL is the API root, overridable for testing against an httptest.Server.
+	BaseURL string
+	//Verbose enables logging of every request and response.
+	Verbose bool
+
+	httpClient *http.Client
+	limiter    *rateLimiter
+	calls      int
+}
+
+//NewClient creates a Client that caps outgoing requests at requestsPerSecond.
+func NewClient(apiKey string, requestsPerSecond float64) *Client {
+	return &Client{
+		APIKey:     apiKey,
+		BaseURL:    defaultBaseURL,
+		httpClient: &http.Client{Timeout: time.Second * 10},
+		limiter:    newRateLimiter(requestsPerSecond),
+	}
+}
+
+//Calls returns the number of HTTP requests made so far, including retries.
+func (c *Client) Calls() int {
+	return c.calls
+}
+
+func (c *Client) logVerbose(format string, a ...interface{}) {
+	if !c.Verbose {
+		return
+	}
+	log.Printf(format, a...)
+}
+
+func (c *Client) get(ctx context.Context, pathAndArgs string, data interface{}) error {
+
+	join := "?"
+	if strings.Contains(pathAndArgs, "?") {
+		join = "&"
+	}
+
+	u := fmt.Sprintf("%s%s%skey=%s", c.BaseURL, pathAndArgs, join, c.APIKey)
+
+	body, statusCode, err := c.doRequestWithRetry(ctx, func() (*http.Request, error) {
+		c.logVerbose("get request for url '%v'", pathAndArgs)
+
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.logVerbose("response %v for get url '%v': %v...", statusCode, pathAndArgs, ellipsis(string(body)))
+
+	if statusCode != http.StatusOK {
+		return boError(u, nil, statusCode, body)
+	}
+
+	if err = json.Unmarshal(body, data); err != nil {
+		return fmt.Errorf("Error parsing json response: %v", err)
+	}
+
+	return nil
+}
+
+//Brick owl API POSTS don't use a body - all args are on the querystring
+func (c *Client) post(ctx context.Context, pathAndArgs string, data url.Values, response interface{}) error {
+
+	data.Set("key", c.APIKey)
+
+	u := fmt.Sprintf("%s%s", c.BaseURL, pathAndArgs)
+
+	body, statusCode, err := c.doRequestWithRetry(ctx, func() (*http.Request, error) {
+		c.logVerbose("post request for url '%v', params: %v", pathAndArgs, data)
+
+		encoded := data.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(encoded))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Add("Content-Length", strconv.Itoa(len(encoded)))
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c.logVerbose("response %v for post url '%v': %v...", statusCode, u, ellipsis(string(body)))
+
+	if statusCode != http.StatusOK {
+		return boError(u, data, statusCode, body)
+	}
+
+	//If a response is required then attempt to decode it
+	if response != nil {
+		if err = json.Unmarshal(body, response); err != nil {
+			return fmt.Errorf("Error parsing json response: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func ellipsis(s string) string {
+
+	const truncateAt = 50
+
+	if len(s) < truncateAt {
+		return s
+	}
+
+	return s[:50] + "..."
+}
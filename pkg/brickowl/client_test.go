@@ -0,0 +1,84 @@
+package brickowl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateLot(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.FormValue("boid"); got != "3001" {
+			t.Errorf("boid = %q, want %q", got, "3001")
+		}
+		fmt.Fprint(w, `{"lot_id":"42"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", 1000)
+	c.BaseURL = server.URL + "/"
+
+	lot, err := c.CreateLot(context.Background(), "928017", "3001", "5")
+	if err != nil {
+		t.Fatalf("CreateLot() error = %v", err)
+	}
+	if lot.ID != "42" {
+		t.Errorf("lot.ID = %q, want %q", lot.ID, "42")
+	}
+	if c.Calls() != 1 {
+		t.Errorf("Calls() = %v, want 1", c.Calls())
+	}
+}
+
+func TestGetRetriesOnServerError(t *testing.T) {
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `[{"wishlist_id":"1","name":"test"}]`)
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", 1000)
+	c.BaseURL = server.URL + "/"
+
+	lists, err := c.ListWishlists(context.Background())
+	if err != nil {
+		t.Fatalf("ListWishlists() error = %v", err)
+	}
+	if len(lists) != 1 || lists[0].Name != "test" {
+		t.Fatalf("ListWishlists() = %+v, want one list named 'test'", lists)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %v, want 3", attempts)
+	}
+}
+
+func TestPostFatalErrorNotRetried(t *testing.T) {
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"status":"invalid boid"}}`)
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key", 1000)
+	c.BaseURL = server.URL + "/"
+
+	_, err := c.CreateLot(context.Background(), "928017", "bad-boid", "5")
+	if err == nil {
+		t.Fatal("CreateLot() error = nil, want an error")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %v, want 1 (fatal errors shouldn't retry)", attempts)
+	}
+}
@@ -0,0 +1,120 @@
+package brickowl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+//ListWishlists returns every wishlist on the account.
+func (c *Client) ListWishlists(ctx context.Context) ([]WishList, error) {
+	var lists []WishList
+	if err := c.get(ctx, "wishlist/lists", &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+//CreateWishlist creates a new wishlist with the given name and description.
+func (c *Client) CreateWishlist(ctx context.Context, name, description string) (WishList, error) {
+
+	values := url.Values{}
+	values.Set("name", name)
+	values.Set("description", description)
+
+	var resp struct {
+		ID string `json:"wishlist_id"`
+	}
+	if err := c.post(ctx, "wishlist/create_list", values, &resp); err != nil {
+		return WishList{}, err
+	}
+
+	return WishList{ID: resp.ID, Name: name, Description: description}, nil
+}
+
+//DeleteWishlist deletes a wishlist and all of its lots.
+func (c *Client) DeleteWishlist(ctx context.Context, listID string) error {
+	values := url.Values{}
+	values.Set("wishlist_id", listID)
+	return c.post(ctx, "wishlist/delete_list", values, nil)
+}
+
+//ListLots returns every lot on a wishlist.
+func (c *Client) ListLots(ctx context.Context, listID string) ([]Lot, error) {
+	var lots []Lot
+	if err := c.get(ctx, fmt.Sprintf("wishlist/lots?wishlist_id=%s", listID), &lots); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+//CreateLot adds a lot for boid/colorID to a wishlist. Brick Owl doesn't accept
+//a quantity on creation - follow up with UpdateLot if it needs to be anything other than 1.
+func (c *Client) CreateLot(ctx context.Context, listID, boid, colorID string) (Lot, error) {
+
+	values := url.Values{}
+	values.Set("boid", boid)
+	values.Set("color_id", colorID)
+	values.Set("wishlist_id", listID)
+
+	var resp struct {
+		ID string `json:"lot_id"`
+	}
+	if err := c.post(ctx, "wishlist/create_lot", values, &resp); err != nil {
+		return Lot{}, err
+	}
+
+	return Lot{ID: resp.ID, BOID: boid, ColorID: colorID, MinimumQuantity: "1"}, nil
+}
+
+//UpdateLot sets the minimum quantity of an existing lot.
+func (c *Client) UpdateLot(ctx context.Context, listID, lotID, quantity string) error {
+	values := url.Values{}
+	values.Set("minimum_quantity", quantity)
+	values.Set("wishlist_id", listID)
+	values.Set("lot_id", lotID)
+	return c.post(ctx, "wishlist/update", values, nil)
+}
+
+//DeleteLot removes a lot from a wishlist.
+func (c *Client) DeleteLot(ctx context.Context, listID, lotID string) error {
+	values := url.Values{}
+	values.Set("wishlist_id", listID)
+	values.Set("lot_id", lotID)
+	return c.post(ctx, "wishlist/delete_lot", values, nil)
+}
+
+//Colors returns the Brick Owl color catalog, keyed by color id.
+func (c *Client) Colors(ctx context.Context) (map[string]Color, error) {
+	var colorData map[string]Color
+	if err := c.get(ctx, "catalog/color_list", &colorData); err != nil {
+		return nil, err
+	}
+
+	for id, color := range colorData {
+		color.ID = id
+		colorData[id] = color
+	}
+
+	return colorData, nil
+}
+
+//LookupBOID looks up Brick Owl part ids (BOIDs) matching partID under the
+//given id_type (eg "ldraw", "design_id", "bl_item_no"), or every match for
+//that part if idType is empty.
+func (c *Client) LookupBOID(ctx context.Context, partID, idType string) ([]string, error) {
+
+	path := fmt.Sprintf("catalog/id_lookup?id=%s&type=Part", partID)
+	if idType != "" {
+		path += "&id_type=" + idType
+	}
+
+	var resp struct {
+		BOIDs []string `json:"boids"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.BOIDs, nil
+}
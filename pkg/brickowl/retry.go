@@ -0,0 +1,188 @@
+package brickowl
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxRetries     = 5
+	baseRetryDelay = 500 * time.Millisecond
+	maxRetryDelay  = 30 * time.Second
+)
+
+//retriableStatuses are Brick Owl error statuses that indicate a transient
+//problem worth retrying, rather than a fatal problem with the request itself.
+var retriableStatuses = map[string]bool{
+	"rate limited":    true,
+	"temporary error": true,
+	"internal error":  true,
+	"server busy":     true,
+}
+
+//rateLimiter enforces a minimum interval between outgoing Brick Owl API requests.
+type rateLimiter struct {
+	mu       chan struct{}
+	interval time.Duration
+	last     time.Time
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = 1
+	}
+	return &rateLimiter{
+		mu:       make(chan struct{}, 1),
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+	}
+}
+
+//wait blocks until it is safe to send another request under the configured rps cap.
+func (r *rateLimiter) wait() {
+	r.mu <- struct{}{}
+	defer func() { <-r.mu }()
+
+	now := time.Now()
+	if earliest := r.last.Add(r.interval); now.Before(earliest) {
+		time.Sleep(earliest.Sub(now))
+		now = time.Now()
+	}
+	r.last = now
+}
+
+//doRequestWithRetry rate limits and sends a request built by buildReq, retrying
+//on transient failures (network errors, 429s, 5xxs, and Brick Owl error statuses
+//in retriableStatuses) with exponential backoff and jitter, honoring any
+//Retry-After header. buildReq is called again on every attempt so callers with
+//a request body (eg post) can safely reset it. ctx can cancel a run between attempts.
+func (c *Client) doRequestWithRetry(ctx context.Context, buildReq func() (*http.Request, error)) (body []byte, statusCode int, err error) {
+
+	delay := baseRetryDelay
+
+	for attempt := 0; ; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+
+		c.limiter.wait()
+
+		req, buildErr := buildReq()
+		if buildErr != nil {
+			return nil, 0, buildErr
+		}
+
+		c.calls++
+		resp, doErr := c.httpClient.Do(req)
+		if doErr != nil {
+			if attempt >= maxRetries {
+				return nil, 0, doErr
+			}
+			c.logVerbose("request error (attempt %v/%v): %v - retrying in %v", attempt+1, maxRetries, doErr, delay)
+			if !sleep(ctx, jitter(delay)) {
+				return nil, 0, ctx.Err()
+			}
+			delay = nextDelay(delay)
+			continue
+		}
+
+		body, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, resp.StatusCode, err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			return body, resp.StatusCode, nil
+		}
+
+		retryable, retryAfter := classifyResponse(resp, body)
+		if !retryable || attempt >= maxRetries {
+			return body, resp.StatusCode, nil
+		}
+
+		wait := retryAfter
+		if wait == 0 {
+			wait = jitter(delay)
+		}
+		c.logVerbose("retriable response %v (attempt %v/%v) - retrying in %v", resp.StatusCode, attempt+1, maxRetries, wait)
+		if !sleep(ctx, wait) {
+			return nil, 0, ctx.Err()
+		}
+		delay = nextDelay(delay)
+	}
+}
+
+//sleep waits for d, returning false early if ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+//classifyResponse decides whether a non-200 response is worth retrying, and
+//how long to wait before doing so.
+func classifyResponse(resp *http.Response, body []byte) (retryable bool, retryAfter time.Duration) {
+
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		retryAfter = parseRetryAfter(ra)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true, retryAfter
+	case resp.StatusCode >= 500:
+		return true, retryAfter
+	case resp.StatusCode >= 400:
+		//Brick Owl puts a status message in the body - some of these are transient.
+		var errorResponse struct {
+			Error struct {
+				Status string `json:"status"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &errorResponse) == nil && retriableStatuses[strings.ToLower(errorResponse.Error.Status)] {
+			return true, retryAfter
+		}
+		return false, retryAfter
+	default:
+		return false, retryAfter
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
+
+func nextDelay(d time.Duration) time.Duration {
+	d *= 2
+	if d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d
+}
+
+//jitter randomizes a delay to avoid retry storms from multiple runs backing off in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half+1)))
+}
@@ -0,0 +1,56 @@
+package brickowl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+//WishList is a Brick Owl wishlist.
+//{"wishlist_id":"928017","name":"6971","description":""}
+type WishList struct {
+	ID          string `json:"wishlist_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+//Lot is a single part/color/quantity entry on a wishlist.
+//{"lot_id":"4471829","boid":"3245c-95","color_id":"5","minimum_quantity":"2"}
+type Lot struct {
+	ID              string `json:"lot_id"`
+	BOID            string `json:"boid"`
+	ColorID         string `json:"color_id"`
+	MinimumQuantity string `json:"minimum_quantity"`
+}
+
+//Color is a Brick Owl catalog color. ID is populated from the map key that
+//catalog/color_list returns it under, since it isn't present in the object itself.
+type Color struct {
+	ID   string
+	Name string `json:"name"`
+	//BLID is the equivalent BrickLink color id, when Brick Owl knows of one.
+	BLID string `json:"bl_id"`
+}
+
+//boError builds an error from a non-200 Brick Owl response, preferring the
+//JSON error status Brick Owl includes in the body over the raw status code.
+func boError(u string, params url.Values, statusCode int, body []byte) error {
+
+	if len(body) > 0 {
+		var errorResponse struct {
+			Error struct {
+				Status string `json:"status"`
+			} `json:"error"`
+		}
+
+		if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Error.Status != "" {
+			if params != nil {
+				return fmt.Errorf("Error from request '%v', '%v': %v", u, params, errorResponse.Error.Status)
+			}
+			return fmt.Errorf("Error from request '%v': %v", u, errorResponse.Error.Status)
+		}
+	}
+
+	//Otherwise return the status code
+	return fmt.Errorf("Error %v from request `%v'", statusCode, u)
+}